@@ -0,0 +1,229 @@
+package dns
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IsDuplicate reports whether r1 and r2 are the same RR for the
+// purposes of zone file duplicate detection: same owner name, class
+// and type, and identical rdata. Domain-name rdata fields are
+// compared case-insensitively; opaque fields (signatures, salts, key
+// material, text strings, ...) are compared bytewise.
+//
+// Unrecognised Rrtypes (no entry in isDuplicateRdata) are never
+// reported as duplicates, since there is no safe generic way to
+// compare their rdata.
+func IsDuplicate(r1, r2 RR) bool {
+	h1, h2 := r1.Header(), r2.Header()
+	if !strings.EqualFold(h1.Name, h2.Name) {
+		return false
+	}
+	if h1.Class != h2.Class || h1.Rrtype != h2.Rrtype {
+		return false
+	}
+	f, ok := isDuplicateRdata[h1.Rrtype]
+	if !ok {
+		return false
+	}
+	return f(r1, r2)
+}
+
+// isDuplicateRdata dispatches to a per-Rrtype rdata comparator,
+// mirroring the setRR dispatch in zscan_rr.go: newly added RR types
+// register their comparator here alongside their setter.
+var isDuplicateRdata = map[uint16]func(RR, RR) bool{
+	TypeA:          isDuplicateA,
+	TypeAAAA:       isDuplicateAAAA,
+	TypeNS:         isDuplicateNS,
+	TypeMX:         isDuplicateMX,
+	TypeCNAME:      isDuplicateCNAME,
+	TypeSOA:        isDuplicateSOA,
+	TypeRRSIG:      isDuplicateRRSIG,
+	TypeNSEC:       isDuplicateNSEC,
+	TypeNSEC3:      isDuplicateNSEC3,
+	TypeNSEC3PARAM: isDuplicateNSEC3PARAM,
+	TypeTXT:        isDuplicateTXT,
+	TypeDS:         isDuplicateDS,
+	TypeDLV:        isDuplicateDLV,
+	TypeTA:         isDuplicateTA,
+	TypeDNSKEY:     isDuplicateDNSKEY,
+	TypeSSHFP:      isDuplicateSSHFP,
+	TypeHIP:        isDuplicateHIP,
+	TypeTLSA:       isDuplicateTLSA,
+}
+
+func isDuplicateA(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_A), r2.(*RR_A)
+	return a1.A.Equal(a2.A)
+}
+
+func isDuplicateAAAA(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_AAAA), r2.(*RR_AAAA)
+	return a1.AAAA.Equal(a2.AAAA)
+}
+
+func isDuplicateNS(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_NS), r2.(*RR_NS)
+	return strings.EqualFold(a1.Ns, a2.Ns)
+}
+
+func isDuplicateMX(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_MX), r2.(*RR_MX)
+	return a1.Pref == a2.Pref && strings.EqualFold(a1.Mx, a2.Mx)
+}
+
+func isDuplicateCNAME(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_CNAME), r2.(*RR_CNAME)
+	return strings.EqualFold(a1.Cname, a2.Cname)
+}
+
+func isDuplicateSOA(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_SOA), r2.(*RR_SOA)
+	return strings.EqualFold(a1.Ns, a2.Ns) &&
+		strings.EqualFold(a1.Mbox, a2.Mbox) &&
+		a1.Serial == a2.Serial &&
+		a1.Refresh == a2.Refresh &&
+		a1.Retry == a2.Retry &&
+		a1.Expire == a2.Expire &&
+		a1.Minttl == a2.Minttl
+}
+
+func isDuplicateRRSIG(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_RRSIG), r2.(*RR_RRSIG)
+	return a1.TypeCovered == a2.TypeCovered &&
+		a1.Algorithm == a2.Algorithm &&
+		a1.Labels == a2.Labels &&
+		a1.OrigTtl == a2.OrigTtl &&
+		a1.Expiration == a2.Expiration &&
+		a1.Inception == a2.Inception &&
+		a1.KeyTag == a2.KeyTag &&
+		strings.EqualFold(a1.SignerName, a2.SignerName) &&
+		a1.Signature == a2.Signature
+}
+
+func isDuplicateNSEC(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_NSEC), r2.(*RR_NSEC)
+	if !strings.EqualFold(a1.NextDomain, a2.NextDomain) {
+		return false
+	}
+	return uint16SliceEqual(a1.TypeBitMap, a2.TypeBitMap)
+}
+
+func isDuplicateNSEC3(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_NSEC3), r2.(*RR_NSEC3)
+	if a1.Hash != a2.Hash || a1.Flags != a2.Flags || a1.Iterations != a2.Iterations {
+		return false
+	}
+	if a1.Salt != a2.Salt || a1.NextDomain != a2.NextDomain {
+		return false
+	}
+	return uint16SliceEqual(a1.TypeBitMap, a2.TypeBitMap)
+}
+
+func isDuplicateNSEC3PARAM(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_NSEC3PARAM), r2.(*RR_NSEC3PARAM)
+	return a1.Hash == a2.Hash && a1.Flags == a2.Flags &&
+		a1.Iterations == a2.Iterations && a1.Salt == a2.Salt
+}
+
+func isDuplicateTXT(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_TXT), r2.(*RR_TXT)
+	return a1.Txt == a2.Txt
+}
+
+func isDuplicateDS(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_DS), r2.(*RR_DS)
+	return a1.KeyTag == a2.KeyTag && a1.Algorithm == a2.Algorithm &&
+		a1.DigestType == a2.DigestType && a1.Digest == a2.Digest
+}
+
+func isDuplicateDLV(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_DLV), r2.(*RR_DLV)
+	return a1.KeyTag == a2.KeyTag && a1.Algorithm == a2.Algorithm &&
+		a1.DigestType == a2.DigestType && a1.Digest == a2.Digest
+}
+
+func isDuplicateTA(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_TA), r2.(*RR_TA)
+	return a1.KeyTag == a2.KeyTag && a1.Algorithm == a2.Algorithm &&
+		a1.DigestType == a2.DigestType && a1.Digest == a2.Digest
+}
+
+func isDuplicateDNSKEY(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_DNSKEY), r2.(*RR_DNSKEY)
+	return a1.Flags == a2.Flags && a1.Protocol == a2.Protocol &&
+		a1.Algorithm == a2.Algorithm && a1.PublicKey == a2.PublicKey
+}
+
+func isDuplicateSSHFP(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_SSHFP), r2.(*RR_SSHFP)
+	return a1.Algorithm == a2.Algorithm && a1.Type == a2.Type &&
+		a1.FingerPrint == a2.FingerPrint
+}
+
+func isDuplicateHIP(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_HIP), r2.(*RR_HIP)
+	if a1.PublicKeyAlgorithm != a2.PublicKeyAlgorithm {
+		return false
+	}
+	if a1.Hit != a2.Hit || a1.PublicKey != a2.PublicKey {
+		return false
+	}
+	if len(a1.RendezvousServers) != len(a2.RendezvousServers) {
+		return false
+	}
+	for i := range a1.RendezvousServers {
+		if !strings.EqualFold(a1.RendezvousServers[i], a2.RendezvousServers[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDuplicateTLSA(r1, r2 RR) bool {
+	a1, a2 := r1.(*RR_TLSA), r2.(*RR_TLSA)
+	return a1.Usage == a2.Usage && a1.Selector == a2.Selector &&
+		a1.MatchingType == a2.MatchingType && a1.Certificate == a2.Certificate
+}
+
+func uint16SliceEqual(a, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dupTracker records RRs seen so far in a zone (scoped across any
+// $INCLUDEd files) so ParseZoneWithOptions can reject duplicates as
+// they are encountered. It is a no-op when RejectDuplicates is false.
+type dupTracker struct {
+	enabled bool
+	seen    map[string][]RR
+}
+
+func newDupTracker(opts ParseZoneOptions) *dupTracker {
+	return &dupTracker{enabled: opts.RejectDuplicates, seen: make(map[string][]RR)}
+}
+
+// check registers rr and returns a ParseError if it duplicates an RR
+// already seen by this tracker.
+func (d *dupTracker) check(rr RR) *ParseError {
+	if !d.enabled {
+		return nil
+	}
+	h := rr.Header()
+	key := strings.ToLower(h.Name) + "/" + strconv.Itoa(int(h.Rrtype)) + "/" + strconv.Itoa(int(h.Class))
+	for _, prev := range d.seen[key] {
+		if IsDuplicate(prev, rr) {
+			return &ParseError{"duplicate RR for " + h.Name, Lex{token: h.Name}}
+		}
+	}
+	d.seen[key] = append(d.seen[key], rr)
+	return nil
+}
@@ -0,0 +1,97 @@
+package dns
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsDuplicateA(t *testing.T) {
+	h := RR_Header{Name: "www.example.com.", Rrtype: TypeA, Class: ClassINET}
+	r1 := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.4")}
+	r2 := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.4")}
+	r3 := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.5")}
+	if !IsDuplicate(r1, r2) {
+		t.Fatalf("expected r1 and r2 to be duplicates")
+	}
+	if IsDuplicate(r1, r3) {
+		t.Fatalf("r1 and r3 differ in A and should not be duplicates")
+	}
+}
+
+func TestIsDuplicateNameIsCaseInsensitive(t *testing.T) {
+	r1 := &RR_NS{Hdr: RR_Header{Name: "example.com.", Rrtype: TypeNS, Class: ClassINET}, Ns: "NS1.EXAMPLE.COM."}
+	r2 := &RR_NS{Hdr: RR_Header{Name: "EXAMPLE.COM.", Rrtype: TypeNS, Class: ClassINET}, Ns: "ns1.example.com."}
+	if !IsDuplicate(r1, r2) {
+		t.Fatalf("expected owner name and NS rdata comparisons to be case-insensitive")
+	}
+}
+
+func TestIsDuplicateDifferentClass(t *testing.T) {
+	h := RR_Header{Name: "www.example.com.", Rrtype: TypeA, Class: ClassINET}
+	r1 := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.4")}
+	r2 := &RR_A{Hdr: RR_Header{Name: h.Name, Rrtype: TypeA, Class: ClassCHAOS}, A: net.ParseIP("1.2.3.4")}
+	if IsDuplicate(r1, r2) {
+		t.Fatalf("records in different classes must not be duplicates")
+	}
+}
+
+func TestIsDuplicateUnregisteredRrtype(t *testing.T) {
+	// An Rrtype with no comparator registered can never safely be
+	// called a duplicate, even of itself.
+	h := RR_Header{Name: "www.example.com.", Rrtype: TypeOPT, Class: ClassINET}
+	r1 := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.4")}
+	if IsDuplicate(r1, r1) {
+		t.Fatalf("an unregistered Rrtype must never be reported as a duplicate")
+	}
+}
+
+func TestIsDuplicateHIP(t *testing.T) {
+	h := RR_Header{Name: "www.example.com.", Rrtype: TypeHIP, Class: ClassINET}
+	r1 := &RR_HIP{Hdr: h, PublicKeyAlgorithm: 2, Hit: "abc", PublicKey: "xyz", RendezvousServers: []string{"RVS.example.com."}}
+	r2 := &RR_HIP{Hdr: h, PublicKeyAlgorithm: 2, Hit: "abc", PublicKey: "xyz", RendezvousServers: []string{"rvs.example.com."}}
+	r3 := &RR_HIP{Hdr: h, PublicKeyAlgorithm: 2, Hit: "abc", PublicKey: "xyz", RendezvousServers: []string{"other.example.com."}}
+	if !IsDuplicate(r1, r2) {
+		t.Fatalf("HIP rendezvous servers should compare case-insensitively")
+	}
+	if IsDuplicate(r1, r3) {
+		t.Fatalf("differing rendezvous servers must not be duplicates")
+	}
+}
+
+func TestDupTrackerDisabledByDefault(t *testing.T) {
+	d := newDupTracker(ParseZoneOptions{})
+	h := RR_Header{Name: "www.example.com.", Rrtype: TypeA, Class: ClassINET}
+	rr := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.4")}
+	if err := d.check(rr); err != nil {
+		t.Fatalf("unexpected error with duplicate rejection disabled: %v", err)
+	}
+	if err := d.check(rr); err != nil {
+		t.Fatalf("a disabled tracker must never report duplicates: %v", err)
+	}
+}
+
+func TestDupTrackerRejectsDuplicate(t *testing.T) {
+	d := newDupTracker(ParseZoneOptions{RejectDuplicates: true})
+	h := RR_Header{Name: "www.example.com.", Rrtype: TypeA, Class: ClassINET}
+	rr1 := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.4")}
+	rr2 := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.4")}
+	if err := d.check(rr1); err != nil {
+		t.Fatalf("first record must not be flagged: %v", err)
+	}
+	if err := d.check(rr2); err == nil {
+		t.Fatalf("identical second record should be flagged as a duplicate")
+	}
+}
+
+func TestDupTrackerAllowsDistinctRecords(t *testing.T) {
+	d := newDupTracker(ParseZoneOptions{RejectDuplicates: true})
+	h := RR_Header{Name: "www.example.com.", Rrtype: TypeA, Class: ClassINET}
+	rr1 := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.4")}
+	rr2 := &RR_A{Hdr: h, A: net.ParseIP("1.2.3.5")}
+	if err := d.check(rr1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.check(rr2); err != nil {
+		t.Fatalf("distinct A records must not be flagged as duplicates: %v", err)
+	}
+}
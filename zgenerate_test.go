@@ -0,0 +1,129 @@
+package dns
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseZoneGenerateBasic(t *testing.T) {
+	zone := "$GENERATE 1-3 host$ IN A 1.2.3.$\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 3 {
+		t.Fatalf("expected 3 tokens, got %d", len(toks))
+	}
+	for i, tok := range toks {
+		if tok.Error != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, tok.Error)
+		}
+	}
+	wantOwners := []string{"host1.example.com.", "host2.example.com.", "host3.example.com."}
+	for i, want := range wantOwners {
+		if got := toks[i].RR.Header().Name; got != want {
+			t.Fatalf("token %d: owner = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestParseZoneGenerateStep(t *testing.T) {
+	zone := "$GENERATE 0-4/2 host$ IN A 1.2.3.$\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 3 {
+		t.Fatalf("expected 3 tokens (0, 2, 4), got %d", len(toks))
+	}
+	wantOwners := []string{"host0.example.com.", "host2.example.com.", "host4.example.com."}
+	for i, want := range wantOwners {
+		if toks[i].Error != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, toks[i].Error)
+		}
+		if got := toks[i].RR.Header().Name; got != want {
+			t.Fatalf("token %d: owner = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestParseZoneGenerateStopBeforeStart(t *testing.T) {
+	zone := "$GENERATE 4-0 host$ IN A 1.2.3.$\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 1 || toks[0].Error == nil {
+		t.Fatalf("expected a single error token for stop < start, got %+v", toks)
+	}
+}
+
+func TestExpandDollarModifiers(t *testing.T) {
+	cases := []struct {
+		in   string
+		v    int64
+		want string
+	}{
+		{"host$", 5, "host5"},
+		{"host$$", 5, "host$"},
+		{"host${0,3,d}", 5, "host005"},
+		{"host${-1,0,d}", 5, "host4"},
+		{"host${0,0,o}", 8, "host10"},
+		{"host${0,2,x}", 255, "hostff"},
+		{"host${0,2,X}", 255, "hostFF"},
+	}
+	for _, c := range cases {
+		got, err := expandDollar(c.in, c.v)
+		if err != nil {
+			t.Fatalf("expandDollar(%q, %d): unexpected error: %v", c.in, c.v, err)
+		}
+		if got != c.want {
+			t.Fatalf("expandDollar(%q, %d) = %q, want %q", c.in, c.v, got, c.want)
+		}
+	}
+}
+
+func TestExpandDollarUnterminatedModifier(t *testing.T) {
+	if _, err := expandDollar("host${0,3,d", 1); err == nil {
+		t.Fatalf("expected error for unterminated ${...} modifier")
+	}
+}
+
+func TestExpandDollarBadBase(t *testing.T) {
+	if _, err := expandDollar("host${0,3,q}", 1); err == nil {
+		t.Fatalf("expected error for bad base letter")
+	}
+}
+
+func TestExpandDollarBadWidth(t *testing.T) {
+	if _, err := expandDollar("host${0,-1,d}", 1); err == nil {
+		t.Fatalf("expected error for negative width")
+	}
+}
+
+func TestExpandDollarTooManyModifierParts(t *testing.T) {
+	if _, err := expandDollar("host${0,3,d,x}", 1); err == nil {
+		t.Fatalf("expected error for a modifier with too many comma-separated parts")
+	}
+}
+
+func TestParseGenerateRangeBadRange(t *testing.T) {
+	if _, _, _, err := parseGenerateRange("bad", "", 1); err == nil {
+		t.Fatalf("expected error for a range with no '-'")
+	}
+}
+
+func TestParseGenerateRangeBadStep(t *testing.T) {
+	if _, _, _, err := parseGenerateRange("1-5/0", "", 1); err == nil {
+		t.Fatalf("expected error for a zero step")
+	}
+	if _, _, _, err := parseGenerateRange("1-5/-2", "", 1); err == nil {
+		t.Fatalf("expected error for a negative step")
+	}
+}
+
+func TestParseZoneGenerateOverlongTtlDoesNotWraparound(t *testing.T) {
+	// A TTL-shaped field so large it would overflow a naively
+	// accumulated uint64 before the old ">0xFFFFFFFF" bounds check
+	// could run; it must be rejected as the RR type instead of
+	// silently wrapping into a small, wrong TTL.
+	zone := "$GENERATE 1-1 host$ 99999999999999999999 IN A 1.2.3.$\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 1 || toks[0].Error == nil {
+		t.Fatalf("expected a single error token for the bogus field, got %+v", toks)
+	}
+	if !strings.Contains(toks[0].Error.Error(), "RR type") {
+		t.Fatalf("expected the overlong field to be treated as an unknown RR type, got: %v", toks[0].Error)
+	}
+}
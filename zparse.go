@@ -0,0 +1,622 @@
+package dns
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A Token is returned on the channel from ParseZone. It either
+// carries a successfully parsed RR, or a ParseError describing why
+// the current record could not be parsed. If the RR (or the
+// directive that produced it) had a trailing "; ..." comment on its
+// closing line, that text is available in Comment.
+type Token struct {
+	RR      RR
+	Error   *ParseError
+	Comment string
+}
+
+// defaultTtl is used for RRs that do not inherit a TTL from a
+// previous record and have no $TTL directive in scope.
+const defaultTtl = 3600
+
+// ParseZone reads a zone file from r and returns a channel of Token,
+// one per RR found in the zone (plus one Token carrying a ParseError
+// for each record that failed to parse). filename is only used to
+// resolve $INCLUDE directives and to annotate errors; it may be left
+// empty if r does not come from a file.
+//
+// ParseZone honors $ORIGIN, $TTL and $INCLUDE. $INCLUDE opens the
+// named file relative to the directory of filename and recursively
+// feeds its records into the same channel; a $INCLUDE cycle results
+// in a Token carrying a ParseError instead of an infinite loop.
+func ParseZone(r io.Reader, origin, filename string) chan Token {
+	return ParseZoneWithOptions(r, origin, filename, ParseZoneOptions{})
+}
+
+// ParseZoneOptions controls the optional checks ParseZoneWithOptions
+// performs while scanning a zone.
+type ParseZoneOptions struct {
+	// RejectDuplicates makes the returned channel emit a ParseError,
+	// instead of the RR, for any record that is a duplicate (per
+	// IsDuplicate) of a record already seen earlier in the zone.
+	RejectDuplicates bool
+}
+
+// ParseZoneWithOptions is like ParseZone but allows the caller to
+// enable additional checks, such as duplicate RR rejection, via opts.
+func ParseZoneWithOptions(r io.Reader, origin, filename string, opts ParseZoneOptions) chan Token {
+	t := make(chan Token, 10000)
+	go func() {
+		defer close(t)
+		seen := make(map[string]bool)
+		if filename != "" {
+			if abs, err := filepath.Abs(filename); err == nil {
+				seen[abs] = true
+			}
+		}
+		parseZone(r, zoneFqdn(origin, "."), filename, t, seen, newDupTracker(opts))
+	}()
+	return t
+}
+
+// parseZone does the actual scanning. It may recurse into itself
+// (via $INCLUDE) feeding Tokens into the same channel t.
+func parseZone(r io.Reader, origin, filename string, t chan Token, seen map[string]bool, dup *dupTracker) {
+	scanner := bufio.NewScanner(r)
+	var (
+		buf          []string // logical record, built up across "(" "...." ")"
+		bufOwnerLine string   // the raw (un-trimmed) first physical line of buf
+		recordLine   int      // line number the current buf started on
+		comment      string
+		paren        int
+		lineno       int
+		ttl          uint32 = defaultTtl
+		lastOwner           = origin
+		lastClass           = "IN"
+	)
+
+	flush := func() {
+		if len(buf) == 0 {
+			return
+		}
+		fields := buf
+		buf = nil
+		com := comment
+		comment = ""
+		line := recordLine
+		// A leading blank/tab on the record's first line means the
+		// owner name was left out and should be inherited, exactly as
+		// BIND zone files define it - not guessed from the shape of
+		// the first token, which is also a legal owner name (e.g. "NS",
+		// "IN", or an all-digit label like "2600").
+		ownerOmitted := len(bufOwnerLine) > 0 && (bufOwnerLine[0] == ' ' || bufOwnerLine[0] == '\t')
+
+		switch strings.ToUpper(fields[0]) {
+		case "$ORIGIN":
+			if len(fields) < 2 {
+				t <- Token{Error: parseErrorAt(filename, line, fields[0], "bad $ORIGIN"), Comment: com}
+				return
+			}
+			origin = zoneFqdn(fields[1], origin)
+			lastOwner = origin
+			return
+		case "$TTL":
+			if len(fields) < 2 {
+				t <- Token{Error: parseErrorAt(filename, line, fields[0], "bad $TTL"), Comment: com}
+				return
+			}
+			n, ok := parseTTLField(fields[1])
+			if !ok {
+				t <- Token{Error: parseErrorAt(filename, line, fields[1], "bad $TTL"), Comment: com}
+				return
+			}
+			ttl = n
+			return
+		case "$INCLUDE":
+			if err := handleInclude(fields, origin, filename, line, t, seen, dup); err != nil {
+				t <- Token{Error: err, Comment: com}
+			}
+			return
+		case "$GENERATE":
+			if err := handleGenerate(fields, lastClass, ttl, origin, filename, line, com, t, dup); err != nil {
+				t <- Token{Error: err, Comment: com}
+			}
+			return
+		}
+		if strings.HasPrefix(fields[0], "$") {
+			t <- Token{Error: parseErrorAt(filename, line, fields[0], "unknown directive "+fields[0]), Comment: com}
+			return
+		}
+
+		owner, class, typ, newTtl, rdata, explicitTtl, err := splitRR(fields, lastOwner, lastClass, ttl, ownerOmitted, filename, line)
+		if err != nil {
+			t <- Token{Error: err, Comment: com}
+			return
+		}
+		lastOwner = owner
+		lastClass = class
+		// An RR that states its own TTL becomes the new "last explicit
+		// TTL" that subsequent TTL-less records inherit - not just
+		// whatever $TTL last set.
+		if explicitTtl {
+			ttl = newTtl
+		}
+
+		rrtype, ok := Str_rr[strings.ToUpper(typ)]
+		if !ok {
+			t <- Token{Error: parseErrorAt(filename, line, typ, "unknown RR type "+typ), Comment: com}
+			return
+		}
+		h := RR_Header{Name: owner, Rrtype: rrtype, Class: classToInt(class), Ttl: newTtl}
+		rr, e := setRR(h, fieldsToLex(rdata, line))
+		if e != nil {
+			tok := typ
+			if pe, ok := e.(*ParseError); ok {
+				tok = pe.Lex.token
+			}
+			t <- Token{Error: parseErrorAt(filename, line, tok, e.Error()), Comment: com}
+			return
+		}
+		if de := dup.check(rr); de != nil {
+			t <- Token{Error: parseErrorAt(filename, line, de.Lex.token, de.Error()), Comment: com}
+			return
+		}
+		t <- Token{RR: rr, Comment: com}
+	}
+
+	for scanner.Scan() {
+		lineno++
+		rawLine := scanner.Text()
+		line, c := splitComment(rawLine)
+		// Only the comment on a record's own physical lines may attach
+		// to it - a free-standing comment line (or one on an earlier,
+		// already-flushed record) must not leak forward onto whatever
+		// RR happens to follow it, so this is an unconditional
+		// overwrite, not "only when c is non-empty".
+		comment = c
+
+		fields, parenDelta := splitFields(line)
+		if len(buf) == 0 && len(fields) > 0 {
+			bufOwnerLine = line
+			recordLine = lineno
+		}
+		paren += parenDelta
+		buf = append(buf, fields...)
+
+		if paren <= 0 {
+			paren = 0
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t <- Token{Error: parseErrorAt(filename, lineno, "", "error reading zone: "+err.Error())}
+		return
+	}
+	flush()
+}
+
+// parseErrorAt builds a ParseError for line (1-based) of filename
+// (which may be empty if the zone did not come from a named file),
+// carrying tok and line in the returned Lex and the file/line in its
+// message so that the
+// error can be traced back to the exact record that produced it -
+// essential once a zone spans $INCLUDEd files or runs to millions of
+// lines.
+func parseErrorAt(filename string, line int, tok, msg string) *ParseError {
+	if filename != "" {
+		msg = fmt.Sprintf("%s:%d: %s", filename, line, msg)
+	} else if line > 0 {
+		msg = fmt.Sprintf("line %d: %s", line, msg)
+	}
+	return &ParseError{msg, Lex{token: tok, line: line}}
+}
+
+// handleInclude opens the file named by a $INCLUDE directive
+// relative to the directory of filename and recursively feeds its
+// records directly into t. Because the recursive parseZone call is
+// given the included file's own path, every ParseError it produces
+// is already annotated with that file (and its own line numbers)
+// rather than the parent's - there is nothing to rewrite afterwards.
+func handleInclude(fields []string, origin, filename string, line int, t chan Token, seen map[string]bool, dup *dupTracker) *ParseError {
+	if len(fields) < 2 {
+		return parseErrorAt(filename, line, fields[0], "bad $INCLUDE")
+	}
+	incFile := fields[1]
+	incOrigin := origin
+	if len(fields) > 2 {
+		incOrigin = zoneFqdn(fields[2], origin)
+	}
+
+	path := incFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(filename), incFile)
+	}
+	abs, aerr := filepath.Abs(path)
+	if aerr != nil {
+		return parseErrorAt(filename, line, incFile, "bad $INCLUDE: "+aerr.Error())
+	}
+	if seen[abs] {
+		return parseErrorAt(filename, line, incFile, "$INCLUDE cycle detected for "+incFile)
+	}
+	f, oerr := os.Open(path)
+	if oerr != nil {
+		return parseErrorAt(filename, line, incFile, "bad $INCLUDE: "+oerr.Error())
+	}
+	defer f.Close()
+
+	seen[abs] = true
+	parseZone(f, incOrigin, path, t, seen, dup)
+	delete(seen, abs)
+	return nil
+}
+
+// generateMaxCount bounds the number of records a single $GENERATE
+// directive may expand to, guarding against pathological zones such
+// as "$GENERATE 0-4294967295 ...".
+const generateMaxCount = 65536
+
+// handleGenerate expands a single $GENERATE directive into zero or
+// more synthetic RRs, feeding each through the ordinary per-RR
+// parser (setRR) and pushing the result onto t.
+func handleGenerate(fields []string, lastClass string, ttl uint32, origin, filename string, line int, com string, t chan Token, dup *dupTracker) *ParseError {
+	if len(fields) < 4 {
+		return parseErrorAt(filename, line, fields[0], "bad $GENERATE")
+	}
+	start, stop, step, err := parseGenerateRange(fields[1], filename, line)
+	if err != nil {
+		return err
+	}
+	lhs := fields[2]
+
+	i := 3
+	rttl := ttl
+	class := lastClass
+	for ; i < len(fields) && i < 5; i++ {
+		switch {
+		case strings.EqualFold(fields[i], "IN") || strings.EqualFold(fields[i], "CH") || strings.EqualFold(fields[i], "HS"):
+			class = strings.ToUpper(fields[i])
+		default:
+			if n, ok := parseTTLField(fields[i]); ok {
+				rttl = n
+				continue
+			}
+			goto foundType
+		}
+	}
+foundType:
+	if i >= len(fields) {
+		return parseErrorAt(filename, line, fields[0], "bad $GENERATE: missing RR type")
+	}
+	typ := fields[i]
+	rhs := fields[i+1:]
+
+	rrtype, ok := Str_rr[strings.ToUpper(typ)]
+	if !ok {
+		return parseErrorAt(filename, line, typ, "bad $GENERATE: unknown RR type "+typ)
+	}
+
+	count := int64(0)
+	for v := start; v <= stop; v += step {
+		count++
+		if count > generateMaxCount {
+			return parseErrorAt(filename, line, fields[1], fmt.Sprintf("bad $GENERATE: range expands to more than %d records", generateMaxCount))
+		}
+
+		owner, e := expandDollar(lhs, v)
+		if e != nil {
+			t <- Token{Error: parseErrorAt(filename, line, lhs, fmt.Sprintf("bad $GENERATE at %d: %s", v, e)), Comment: com}
+			continue
+		}
+		owner = zoneFqdn(owner, origin)
+
+		rdata := make([]string, len(rhs))
+		skip := false
+		for j, f := range rhs {
+			rf, e := expandDollar(f, v)
+			if e != nil {
+				t <- Token{Error: parseErrorAt(filename, line, f, fmt.Sprintf("bad $GENERATE at %d: %s", v, e)), Comment: com}
+				skip = true
+				break
+			}
+			rdata[j] = rf
+		}
+		if skip {
+			continue
+		}
+
+		h := RR_Header{Name: owner, Rrtype: rrtype, Class: classToInt(class), Ttl: rttl}
+		rr, e := setRR(h, fieldsToLex(rdata, line))
+		if e != nil {
+			t <- Token{Error: parseErrorAt(filename, line, fields[0], fmt.Sprintf("bad $GENERATE at %d: %s", v, e)), Comment: com}
+			continue
+		}
+		if de := dup.check(rr); de != nil {
+			t <- Token{Error: parseErrorAt(filename, line, de.Lex.token, de.Error()), Comment: com}
+			continue
+		}
+		t <- Token{RR: rr, Comment: com}
+	}
+	return nil
+}
+
+// parseGenerateRange parses the "start-stop[/step]" range argument
+// of a $GENERATE directive. step defaults to 1 and stop must not be
+// smaller than start.
+func parseGenerateRange(s, filename string, line int) (start, stop, step int64, err *ParseError) {
+	step = 1
+	rangePart := s
+	if idx := strings.Index(s, "/"); idx >= 0 {
+		rangePart = s[:idx]
+		n, e := strconv.ParseInt(s[idx+1:], 10, 64)
+		if e != nil || n <= 0 {
+			return 0, 0, 0, parseErrorAt(filename, line, s, "bad $GENERATE range step")
+		}
+		step = n
+	}
+	idx := strings.Index(rangePart, "-")
+	if idx <= 0 {
+		return 0, 0, 0, parseErrorAt(filename, line, s, "bad $GENERATE range")
+	}
+	start, e1 := strconv.ParseInt(rangePart[:idx], 10, 64)
+	stop, e2 := strconv.ParseInt(rangePart[idx+1:], 10, 64)
+	if e1 != nil || e2 != nil {
+		return 0, 0, 0, parseErrorAt(filename, line, s, "bad $GENERATE range")
+	}
+	if stop < start {
+		return 0, 0, 0, parseErrorAt(filename, line, s, "bad $GENERATE range: stop before start")
+	}
+	return start, stop, step, nil
+}
+
+// expandDollar substitutes every "$" in s with the iterator value v,
+// following the $GENERATE modifier syntax "${offset,width,base}". A
+// literal "$" is written as "$$".
+func expandDollar(s string, v int64) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '$' {
+			b.WriteByte('$')
+			i++
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '{' {
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated ${...} modifier")
+			}
+			mod := s[i+2 : i+2+end]
+			formatted, err := formatGenerateValue(mod, v)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(formatted)
+			i += 2 + end
+			continue
+		}
+		b.WriteString(formatGenerateInt(v, 'd', 0))
+	}
+	return b.String(), nil
+}
+
+// formatGenerateValue implements the "offset,width,base" modifier:
+// offset is a signed integer added to v, width is the minimum
+// zero-padded field width, and base is one of d, o, x, X.
+func formatGenerateValue(mod string, v int64) (string, error) {
+	parts := strings.Split(mod, ",")
+	offset := int64(0)
+	width := 0
+	base := byte('d')
+	if len(parts) > 0 && parts[0] != "" {
+		n, e := strconv.ParseInt(parts[0], 10, 64)
+		if e != nil {
+			return "", fmt.Errorf("bad $GENERATE offset %q", parts[0])
+		}
+		offset = n
+	}
+	if len(parts) > 1 && parts[1] != "" {
+		n, e := strconv.ParseInt(parts[1], 10, 64)
+		if e != nil || n < 0 {
+			return "", fmt.Errorf("bad $GENERATE width %q", parts[1])
+		}
+		width = int(n)
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		if len(parts[2]) != 1 || !strings.ContainsRune("doxX", rune(parts[2][0])) {
+			return "", fmt.Errorf("bad $GENERATE base %q", parts[2])
+		}
+		base = parts[2][0]
+	}
+	if len(parts) > 3 {
+		return "", fmt.Errorf("bad $GENERATE modifier %q", mod)
+	}
+	return formatGenerateInt(v+offset, base, width), nil
+}
+
+// formatGenerateInt renders v in the given base (d, o, x or X),
+// zero-padded to at least width characters.
+func formatGenerateInt(v int64, base byte, width int) string {
+	var s string
+	switch base {
+	case 'o':
+		s = strconv.FormatInt(v, 8)
+	case 'x':
+		s = strconv.FormatInt(v, 16)
+	case 'X':
+		s = strings.ToUpper(strconv.FormatInt(v, 16))
+	default:
+		s = strconv.FormatInt(v, 10)
+	}
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	for len(s) < width {
+		s = "0" + s
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// splitRR pulls owner/class/type off the front of a record's fields,
+// applying the usual BIND defaulting rules. ownerOmitted tells it
+// whether the zone file line itself left the owner out (leading
+// whitespace), which is the only reliable signal - an owner name can
+// legally be anything a class or RR type keyword could also be (e.g.
+// "NS", "IN", "2600"). It also reports whether the record stated its
+// own TTL, so the caller can make that the new "last explicit TTL".
+func splitRR(fields []string, lastOwner, lastClass string, ttl uint32, ownerOmitted bool, filename string, line int) (owner, class, typ string, rttl uint32, rdata []string, explicitTtl bool, err *ParseError) {
+	if len(fields) == 0 {
+		return "", "", "", 0, nil, false, parseErrorAt(filename, line, "", "empty record")
+	}
+	i := 0
+	owner = lastOwner
+	if !ownerOmitted {
+		owner = fields[0]
+		i++
+	}
+	class = lastClass
+	rttl = ttl
+	for ; i < len(fields) && i < 4; i++ {
+		switch {
+		case strings.EqualFold(fields[i], "IN") || strings.EqualFold(fields[i], "CH") || strings.EqualFold(fields[i], "HS"):
+			class = strings.ToUpper(fields[i])
+		default:
+			if n, ok := parseTTLField(fields[i]); ok {
+				rttl = n
+				explicitTtl = true
+				continue
+			}
+			goto foundType
+		}
+	}
+foundType:
+	if i >= len(fields) {
+		return "", "", "", 0, nil, false, parseErrorAt(filename, line, "", "missing RR type")
+	}
+	typ = fields[i]
+	return owner, class, typ, rttl, fields[i+1:], explicitTtl, nil
+}
+
+func classToInt(class string) uint16 {
+	switch strings.ToUpper(class) {
+	case "CH":
+		return ClassCHAOS
+	case "HS":
+		return ClassHESIOD
+	default:
+		return ClassINET
+	}
+}
+
+// parseTTLField reports whether s is a valid decimal uint32 (a TTL,
+// including the optional TTL field of $GENERATE) and returns its value. Using
+// strconv.ParseUint both recognizes the field and converts it in one
+// overflow-checked pass, rather than accumulating into a uint64 by
+// hand and only checking for overflow afterwards.
+func parseTTLField(s string) (uint32, bool) {
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(n), true
+}
+
+// fieldsToLex turns a slice of already-whitespace-split rdata fields
+// into the Lex channel setRR expects: alternating _STRING/_BLANK,
+// terminated by _EOF, each carrying the zone file line it came from
+// so a ParseError raised deep inside a setX function still points at
+// the right place.
+func fieldsToLex(fields []string, line int) chan Lex {
+	c := make(chan Lex, len(fields)*2+1)
+	for i, f := range fields {
+		if i > 0 {
+			c <- Lex{value: _BLANK, token: " ", line: line}
+		}
+		c <- Lex{value: _STRING, token: f, line: line}
+	}
+	c <- Lex{value: _EOF, token: "", line: line}
+	close(c)
+	return c
+}
+
+// splitFields splits a zone file line into whitespace-separated
+// fields, the same way strings.Fields would, except it understands
+// double-quoted strings the way a zone file needs them to work: a
+// quoted field may contain whitespace and "(" / ")" without being
+// split apart or affecting paren-nesting, and the surrounding quotes
+// are stripped from the field it returns. parenDelta is the net
+// nesting change contributed by any "(" / ")" found outside quotes,
+// for the caller to add to its running paren depth.
+func splitFields(line string) (fields []string, parenDelta int) {
+	var b strings.Builder
+	inQuote, hasField := false, false
+	flush := func() {
+		if hasField {
+			fields = append(fields, b.String())
+			b.Reset()
+			hasField = false
+		}
+	}
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			inQuote = !inQuote
+			hasField = true
+		case inQuote:
+			b.WriteByte(c)
+		case c == '(':
+			parenDelta++
+		case c == ')':
+			parenDelta--
+		case c == ' ' || c == '\t':
+			flush()
+		default:
+			b.WriteByte(c)
+			hasField = true
+		}
+	}
+	flush()
+	return fields, parenDelta
+}
+
+// splitComment strips a trailing "; ..." comment off a zone file
+// line (ignoring semicolons inside double quotes) and returns the
+// remaining line together with the comment text, if any.
+func splitComment(line string) (rest, comment string) {
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inQuote = !inQuote
+		case ';':
+			if !inQuote {
+				return line[:i], strings.TrimSpace(line[i+1:])
+			}
+		}
+	}
+	return line, ""
+}
+
+// zoneFqdn makes s fully qualified against origin, following the
+// usual zone file "@" and trailing-dot conventions.
+func zoneFqdn(s, origin string) string {
+	if s == "@" || s == "" {
+		return origin
+	}
+	if strings.HasSuffix(s, ".") {
+		return s
+	}
+	return s + "." + origin
+}
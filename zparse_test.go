@@ -0,0 +1,301 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func collectTokens(t *testing.T, zone string) []Token {
+	t.Helper()
+	var toks []Token
+	for tok := range ParseZone(strings.NewReader(zone), "example.com.", "") {
+		toks = append(toks, tok)
+	}
+	return toks
+}
+
+func TestParseZoneOwnerNameLooksLikeKeyword(t *testing.T) {
+	// "NS" and "2600" are legal owner names even though they also look
+	// like a class/type keyword or an all-digit TTL; only the leading
+	// whitespace on the line - not the shape of the first token - may
+	// be used to decide the owner was omitted.
+	zone := "NS IN A 1.2.3.4\n2600 IN A 1.2.3.5\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(toks))
+	}
+	for i, want := range []string{"NS", "2600"} {
+		if toks[i].Error != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, toks[i].Error)
+		}
+		if got := toks[i].RR.Header().Name; got != want {
+			t.Fatalf("token %d: owner = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestParseZoneOwnerOmittedByIndentation(t *testing.T) {
+	zone := "www IN A 1.2.3.4\n  IN A 1.2.3.5\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(toks))
+	}
+	if toks[1].Error != nil {
+		t.Fatalf("unexpected error: %v", toks[1].Error)
+	}
+	if got := toks[1].RR.Header().Name; got != "www" {
+		t.Fatalf("second record should inherit owner \"www\", got %q", got)
+	}
+}
+
+func TestParseZoneInheritsExplicitTtl(t *testing.T) {
+	zone := "www IN 7200 A 1.2.3.4\nwww IN A 1.2.3.5\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(toks))
+	}
+	for i, tok := range toks {
+		if tok.Error != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, tok.Error)
+		}
+	}
+	if toks[0].RR.Header().Ttl != 7200 {
+		t.Fatalf("first record ttl = %d, want 7200", toks[0].RR.Header().Ttl)
+	}
+	if toks[1].RR.Header().Ttl != 7200 {
+		t.Fatalf("second record should inherit ttl 7200 from the previous record, got %d", toks[1].RR.Header().Ttl)
+	}
+}
+
+func TestParseZoneDollarTtlThenOverride(t *testing.T) {
+	zone := "$TTL 300\nwww IN A 1.2.3.4\nwww IN 900 A 1.2.3.5\nwww IN A 1.2.3.6\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 3 {
+		t.Fatalf("expected 3 tokens, got %d", len(toks))
+	}
+	for i, tok := range toks {
+		if tok.Error != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, tok.Error)
+		}
+	}
+	if got := toks[0].RR.Header().Ttl; got != 300 {
+		t.Fatalf("first record should use $TTL 300, got %d", got)
+	}
+	if got := toks[2].RR.Header().Ttl; got != 900 {
+		t.Fatalf("third record should inherit the explicit ttl 900 from the second record, got %d", got)
+	}
+}
+
+func TestParseZoneErrorHasLineNumber(t *testing.T) {
+	zone := "www IN A 1.2.3.4\nwww IN BOGUSTYPE 1.2.3.5\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(toks))
+	}
+	if toks[1].Error == nil {
+		t.Fatalf("expected an error for the unknown RR type")
+	}
+	if toks[1].Error.Lex.line != 2 {
+		t.Fatalf("error line = %d, want 2", toks[1].Error.Lex.line)
+	}
+}
+
+func TestParseZoneIncludeCycleDetected(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.zone")
+	b := filepath.Join(dir, "b.zone")
+	if err := os.WriteFile(a, []byte("www IN A 1.2.3.4\n$INCLUDE "+b+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("$INCLUDE "+a+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var sawCycleErr bool
+	for tok := range ParseZone(f, "example.com.", a) {
+		if tok.Error != nil && strings.Contains(tok.Error.Error(), "cycle") {
+			sawCycleErr = true
+		}
+	}
+	if !sawCycleErr {
+		t.Fatalf("expected a $INCLUDE cycle to be reported as a ParseError")
+	}
+}
+
+func TestParseZoneIncludeBringsInRecords(t *testing.T) {
+	dir := t.TempDir()
+	inc := filepath.Join(dir, "inc.zone")
+	if err := os.WriteFile(inc, []byte("extra IN A 9.9.9.9\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(dir, "main.zone")
+	zone := "www IN A 1.2.3.4\n$INCLUDE " + inc + "\n"
+	if err := os.WriteFile(main, []byte(zone), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var names []string
+	for tok := range ParseZone(f, "example.com.", main) {
+		if tok.Error != nil {
+			t.Fatalf("unexpected error: %v", tok.Error)
+		}
+		names = append(names, tok.RR.Header().Name)
+	}
+	if len(names) != 2 || names[0] != "www" || names[1] != "extra" {
+		t.Fatalf("expected [www extra], got %v", names)
+	}
+}
+
+func TestParseZoneRejectDuplicates(t *testing.T) {
+	zone := "www IN A 1.2.3.4\nwww IN A 1.2.3.4\n"
+	t1 := make(chan Token, 10)
+	seen := make(map[string]bool)
+	go func() {
+		defer close(t1)
+		parseZone(strings.NewReader(zone), "example.com.", "", t1, seen, newDupTracker(ParseZoneOptions{RejectDuplicates: true}))
+	}()
+	var toks []Token
+	for tok := range t1 {
+		toks = append(toks, tok)
+	}
+	if len(toks) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(toks))
+	}
+	if toks[0].Error != nil {
+		t.Fatalf("first record should not be a duplicate: %v", toks[0].Error)
+	}
+	if toks[1].Error == nil {
+		t.Fatalf("second (identical) record should be reported as a duplicate")
+	}
+}
+
+func TestParseZoneQuotedTxtField(t *testing.T) {
+	zone := `www IN TXT "hello world"` + "\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 1 {
+		t.Fatalf("expected 1 token, got %d", len(toks))
+	}
+	if toks[0].Error != nil {
+		t.Fatalf("unexpected error: %v", toks[0].Error)
+	}
+	rr, ok := toks[0].RR.(*RR_TXT)
+	if !ok {
+		t.Fatalf("expected *RR_TXT, got %T", toks[0].RR)
+	}
+	if rr.Txt != "hello world" {
+		t.Fatalf("Txt = %q, want the quoted field split as one field with quotes stripped", rr.Txt)
+	}
+}
+
+func TestParseZoneQuotedParenDoesNotUnbalance(t *testing.T) {
+	zone := `www IN TXT "a (b"` + "\n" + "second IN A 1.2.3.4\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 2 {
+		t.Fatalf("expected 2 tokens (a paren inside a quoted field must not swallow the next record), got %d: %+v", len(toks), toks)
+	}
+	for i, tok := range toks {
+		if tok.Error != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, tok.Error)
+		}
+	}
+	txt, ok := toks[0].RR.(*RR_TXT)
+	if !ok {
+		t.Fatalf("expected *RR_TXT, got %T", toks[0].RR)
+	}
+	if txt.Txt != "a (b" {
+		t.Fatalf("Txt = %q, want %q", txt.Txt, "a (b")
+	}
+	if toks[1].RR.Header().Name != "second" {
+		t.Fatalf("second record owner = %q, want %q", toks[1].RR.Header().Name, "second")
+	}
+}
+
+func TestParseZoneStandaloneCommentDoesNotAttachToNextRR(t *testing.T) {
+	zone := "; Zone file for example.com\n\nwww IN A 1.2.3.4\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 1 {
+		t.Fatalf("expected 1 token, got %d", len(toks))
+	}
+	if toks[0].Comment != "" {
+		t.Fatalf("Comment = %q, want empty - a free-standing header comment must not leak onto the next RR", toks[0].Comment)
+	}
+}
+
+func TestParseZoneMultiLineCommentOnlyFromClosingLine(t *testing.T) {
+	zone := "www IN A ( ; opening comment\n" +
+		"  1.2.3.4 ) ; closing comment\n"
+	toks := collectTokens(t, zone)
+	if len(toks) != 1 {
+		t.Fatalf("expected 1 token, got %d", len(toks))
+	}
+	if toks[0].Error != nil {
+		t.Fatalf("unexpected error: %v", toks[0].Error)
+	}
+	if toks[0].Comment != "closing comment" {
+		t.Fatalf("Comment = %q, want the comment from the record's own closing line only", toks[0].Comment)
+	}
+}
+
+func TestSplitFieldsQuoting(t *testing.T) {
+	fields, paren := splitFields(`a "b c" d`)
+	want := []string{"a", "b c", "d"}
+	if len(fields) != len(want) {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Fatalf("fields[%d] = %q, want %q", i, fields[i], want[i])
+		}
+	}
+	if paren != 0 {
+		t.Fatalf("paren delta = %d, want 0", paren)
+	}
+}
+
+func TestSplitFieldsParenInsideQuoteIsLiteral(t *testing.T) {
+	fields, paren := splitFields(`"a (b" c)`)
+	if len(fields) != 2 || fields[0] != "a (b" || fields[1] != "c" {
+		t.Fatalf("fields = %v, want [\"a (b\" \"c\"]", fields)
+	}
+	if paren != -1 {
+		t.Fatalf("paren delta = %d, want -1 (only the unquoted \")\" should count)", paren)
+	}
+}
+
+func TestSplitComment(t *testing.T) {
+	rest, comment := splitComment(`www IN TXT "a;b" ; trailing note`)
+	if rest != `www IN TXT "a;b" ` {
+		t.Fatalf("rest = %q", rest)
+	}
+	if comment != "trailing note" {
+		t.Fatalf("comment = %q", comment)
+	}
+}
+
+func TestZoneFqdn(t *testing.T) {
+	cases := []struct{ s, origin, want string }{
+		{"@", "example.com.", "example.com."},
+		{"", "example.com.", "example.com."},
+		{"www", "example.com.", "www.example.com."},
+		{"www.other.com.", "example.com.", "www.other.com."},
+	}
+	for _, c := range cases {
+		if got := zoneFqdn(c.s, c.origin); got != c.want {
+			t.Fatalf("zoneFqdn(%q, %q) = %q, want %q", c.s, c.origin, got, c.want)
+		}
+	}
+}
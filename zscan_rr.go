@@ -85,6 +85,22 @@ func setRR(h RR_Header, c chan Lex) (RR, error) {
 		if se := slurpRemainder(c); se != nil {
 			return nil, se
 		}
+	case TypeNSEC3PARAM:
+		r, e = setNSEC3PARAM(h, c)
+		if e != nil {
+			return nil, e
+		}
+		if se := slurpRemainder(c); se != nil {
+			return nil, se
+		}
+	case TypeSSHFP:
+		r, e = setSSHFP(h, c)
+		if e != nil {
+			return nil, e
+		}
+		if se := slurpRemainder(c); se != nil {
+			return nil, se
+		}
 	// These types have a variable ending either chunks of txt or chunks/base64 or hex.
 	// They need to search for the end of the RR themselves, hence they look for the ending
 	// newline. Thus there is no need to slurp the remainder, because there is none
@@ -96,9 +112,21 @@ func setRR(h RR_Header, c chan Lex) (RR, error) {
 		r, e = setNSEC3(h, c)
 	case TypeTXT:
 		r, e = setTXT(h, c)
+	case TypeDS:
+		r, e = setDS(h, c)
+	case TypeDLV:
+		r, e = setDLV(h, c)
+	case TypeTA:
+		r, e = setTA(h, c)
+	case TypeDNSKEY:
+		r, e = setDNSKEY(h, c)
+	case TypeHIP:
+		r, e = setHIP(h, c)
+	case TypeTLSA:
+		r, e = setTLSA(h, c)
 	default:
-                // Don't the have the token the holds the RRtype
-		return nil, &ParseError{"Unknown RR type", Lex{} }
+		// Don't have the token that holds the RRtype
+		return nil, &ParseError{"Unknown RR type", Lex{}}
 	}
 	return r, e
 }
@@ -373,31 +401,36 @@ func setNSEC3(h RR_Header, c chan Lex) (RR, error) {
 	return rr, nil
 }
 
-/*
 func setNSEC3PARAM(h RR_Header, c chan Lex) (RR, error) {
-        rr := new(RR_NSEC3PARAM)
-        rr.Hdr = h
-        rdf := fields(data[mark:p], 4)
-        if i, e = strconv.Atoi(rdf[0]); e != nil {
-                zp.Err <- &ParseError{Error: "bad NSEC3PARAM", name: rdf[0], line: l}
-                return
-        }
-        rr.Hash = uint8(i)
-        if i, e = strconv.Atoi(rdf[1]); e != nil {
-                zp.Err <- &ParseError{Error: "bad NSEC3PARAM", name: rdf[1], line: l}
-                return
-        }
-        rr.Flags = uint8(i)
-        if i, e = strconv.Atoi(rdf[2]); e != nil {
-                zp.Err <- &ParseError{Error: "bad NSEC3PARAM", name: rdf[2], line: l}
-                return
-        }
-        rr.Iterations = uint16(i)
-        rr.Salt = rdf[3]
-        rr.SaltLength = uint8(len(rr.Salt))
-        zp.RR <- rr
-    }
-*/
+	rr := new(RR_NSEC3PARAM)
+	rr.Hdr = h
+
+	l := <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad NSEC3PARAM", l}
+	} else {
+		rr.Hash = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad NSEC3PARAM", l}
+	} else {
+		rr.Flags = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad NSEC3PARAM", l}
+	} else {
+		rr.Iterations = uint16(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	rr.Salt = l.token
+	rr.SaltLength = uint8(len(rr.Salt))
+	return rr, nil
+}
 
 func setTXT(h RR_Header, c chan Lex) (RR, error) {
 	rr := new(RR_TXT)
@@ -421,155 +454,281 @@ func setTXT(h RR_Header, c chan Lex) (RR, error) {
 	return rr, nil
 }
 
-/*
 func setDS(h RR_Header, c chan Lex) (RR, error) {
-        rr := new(RR_DS)
-        rr.Hdr = h
-    action setDS {
-        var (
-                i uint
-                e os.Error
-        )
-        rdf := fields(data[mark:p], 4)
-        rr := new(RR_DS)
-        rr.Hdr = hdr
-        rr.Hdr.Rrtype = TypeDS
-        if i, e = strconv.Atoi(rdf[0]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DS", name: rdf[0], line: l}
-                return
-        }
-        rr.KeyTag = uint16(i)
-        if i, e = strconv.Atoi(rdf[1]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DS", name: rdf[1], line: l}
-                return
-        }
-        rr.Algorithm = uint8(i)
-        if i, e = strconv.Atoi(rdf[2]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DS", name: rdf[2], line: l}
-                return
-        }
-        rr.DigestType = uint8(i)
-        rr.Digest = rdf[3]
-        zp.RR <- rr
-    }
+	rr := new(RR_DS)
+	rr.Hdr = h
 
-func setCNAME(h RR_Header, c chan Lex) (RR, error) {
-        rr := new(RR_CNAME)
-        rr.Hdr = h
-    action setDLV {
-        var (
-                i uint
-                e os.Error
-        )
-        rdf := fields(data[mark:p], 4)
-        rr := new(RR_DLV)
-        rr.Hdr = hdr
-        rr.Hdr.Rrtype = TypeDLV
-        if i, e = strconv.Atoi(rdf[0]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DS", name: rdf[0], line: l}
-                return
-        }
-        rr.KeyTag = uint16(i)
-        if i, e = strconv.Atoi(rdf[1]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DS", name: rdf[1], line: l}
-                return
-        }
-        rr.Algorithm = uint8(i)
-        if i, e = strconv.Atoi(rdf[2]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DS", name: rdf[2], line: l}
-                return
-        }
-        rr.DigestType = uint8(i)
-        rr.Digest = rdf[3]
-        zp.RR <- rr
-    }
+	l := <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad DS", l}
+	} else {
+		rr.KeyTag = uint16(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad DS", l}
+	} else {
+		rr.Algorithm = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad DS", l}
+	} else {
+		rr.DigestType = uint8(i)
+	}
+	<-c // _BLANK
+	// Get the remaining data until we see a NEWLINE
+	l = <-c
+	var s string
+	for l.value != _NEWLINE && l.value != _EOF {
+		switch l.value {
+		case _STRING:
+			s += l.token
+		case _BLANK:
+			// Ok
+		default:
+			return nil, &ParseError{"bad DS", l}
+		}
+		l = <-c
+	}
+	rr.Digest = s
+	return rr, nil
+}
 
-func setCNAME(h RR_Header, c chan Lex) (RR, error) {
-        rr := new(RR_CNAME)
-        rr.Hdr = h
-    action setTA {
-        var (
-                i uint
-                e os.Error
-        )
-        rdf := fields(data[mark:p], 4)
-        rr := new(RR_TA)
-        rr.Hdr = hdr
-        rr.Hdr.Rrtype = TypeTA
-        if i, e = strconv.Atoi(rdf[0]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DS", name: rdf[0], line: l}
-                return
-        }
-        rr.KeyTag = uint16(i)
-        if i, e = strconv.Atoi(rdf[1]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DS", name: rdf[1], line: l}
-                return
-        }
-        rr.Algorithm = uint8(i)
-        if i, e = strconv.Atoi(rdf[2]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DS", name: rdf[2], line: l}
-                return
-        }
-        rr.DigestType = uint8(i)
-        rr.Digest = rdf[3]
-        zp.RR <- rr
-    }
+func setDLV(h RR_Header, c chan Lex) (RR, error) {
+	rr := new(RR_DLV)
+	rr.Hdr = h
 
-func setCNAME(h RR_Header, c chan Lex) (RR, error) {
-        rr := new(RR_CNAME)
-        rr.Hdr = h
-    action setDNSKEY {
-        var (
-                i uint
-                e os.Error
-        )
-        rdf := fields(data[mark:p], 4)
-        rr := new(RR_DNSKEY)
-        rr.Hdr = hdr
-        rr.Hdr.Rrtype = TypeDNSKEY
-
-        if i, e = strconv.Atoi(rdf[0]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DNSKEY", name: rdf[0], line: l}
-                return
-        }
-        rr.Flags = uint16(i)
-        if i, e = strconv.Atoi(rdf[1]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DNSKEY", name: rdf[1], line: l}
-                return
-        }
-        rr.Protocol = uint8(i)
-        if i, e = strconv.Atoi(rdf[2]); e != nil {
-                zp.Err <- &ParseError{Error: "bad DNSKEY", name: rdf[2], line: l}
-                return
-        }
-        rr.Algorithm = uint8(i)
-        rr.PublicKey = rdf[3]
-        zp.RR <- rr
-    }
+	l := <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad DLV", l}
+	} else {
+		rr.KeyTag = uint16(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad DLV", l}
+	} else {
+		rr.Algorithm = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad DLV", l}
+	} else {
+		rr.DigestType = uint8(i)
+	}
+	<-c // _BLANK
+	// Get the remaining data until we see a NEWLINE
+	l = <-c
+	var s string
+	for l.value != _NEWLINE && l.value != _EOF {
+		switch l.value {
+		case _STRING:
+			s += l.token
+		case _BLANK:
+			// Ok
+		default:
+			return nil, &ParseError{"bad DLV", l}
+		}
+		l = <-c
+	}
+	rr.Digest = s
+	return rr, nil
+}
+
+func setTA(h RR_Header, c chan Lex) (RR, error) {
+	rr := new(RR_TA)
+	rr.Hdr = h
+
+	l := <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad TA", l}
+	} else {
+		rr.KeyTag = uint16(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad TA", l}
+	} else {
+		rr.Algorithm = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad TA", l}
+	} else {
+		rr.DigestType = uint8(i)
+	}
+	<-c // _BLANK
+	// Get the remaining data until we see a NEWLINE
+	l = <-c
+	var s string
+	for l.value != _NEWLINE && l.value != _EOF {
+		switch l.value {
+		case _STRING:
+			s += l.token
+		case _BLANK:
+			// Ok
+		default:
+			return nil, &ParseError{"bad TA", l}
+		}
+		l = <-c
+	}
+	rr.Digest = s
+	return rr, nil
+}
 
+func setDNSKEY(h RR_Header, c chan Lex) (RR, error) {
+	rr := new(RR_DNSKEY)
+	rr.Hdr = h
+
+	l := <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad DNSKEY", l}
+	} else {
+		rr.Flags = uint16(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad DNSKEY", l}
+	} else {
+		rr.Protocol = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad DNSKEY", l}
+	} else {
+		rr.Algorithm = uint8(i)
+	}
+	<-c // _BLANK
+	// Get the remaining data (base64 encoded, possibly split over
+	// several blank-separated chunks) until we see a NEWLINE
+	l = <-c
+	var s string
+	for l.value != _NEWLINE && l.value != _EOF {
+		switch l.value {
+		case _STRING:
+			s += l.token
+		case _BLANK:
+			// Ok
+		default:
+			return nil, &ParseError{"bad DNSKEY", l}
+		}
+		l = <-c
+	}
+	rr.PublicKey = s
+	return rr, nil
+}
 
 func setSSHFP(h RR_Header, c chan Lex) (RR, error) {
-        rr := new(RR_CNAME)
-        rr.Hdr = h
-        var (
-                i int
-                e os.Error
-        )
-        rdf := fields(data[mark:p], 3)
-        rr := new(RR_SSHFP)
-        rr.Hdr = hdr
-        rr.Hdr.Rrtype = TypeSSHFP
-        if i, e = strconv.Atoi(rdf[0]); e != nil {
-                zp.Err <- &ParseError{Error: "bad SSHFP", name: rdf[0], line: l}
-                return
-        }
-        rr.Algorithm = uint8(i)
-        if i, e = strconv.Atoi(rdf[1]); e != nil {
-                zp.Err <- &ParseError{Error: "bad SSHFP", name: rdf[1], line: l}
-                return
-        }
-        rr.Type = uint8(i)
-        rr.FingerPrint = rdf[2]
-        zp.RR <- rr
+	rr := new(RR_SSHFP)
+	rr.Hdr = h
+
+	l := <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad SSHFP", l}
+	} else {
+		rr.Algorithm = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad SSHFP", l}
+	} else {
+		rr.Type = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	rr.FingerPrint = l.token
+	return rr, nil
+}
+
+func setHIP(h RR_Header, c chan Lex) (RR, error) {
+	rr := new(RR_HIP)
+	rr.Hdr = h
+
+	l := <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad HIP", l}
+	} else {
+		rr.PublicKeyAlgorithm = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	rr.Hit = l.token
+	rr.HitLength = uint8(len(rr.Hit)) / 2
+	<-c // _BLANK
+	l = <-c
+	rr.PublicKey = l.token
+	rr.PublicKeyLength = uint16(len(rr.PublicKey))
+
+	// Rendezvous servers, zero or more, until the NEWLINE
+	rr.RendezvousServers = make([]string, 0)
+	l = <-c
+	for l.value != _NEWLINE && l.value != _EOF {
+		switch l.value {
+		case _STRING:
+			if !IsDomainName(l.token) {
+				return nil, &ParseError{"bad HIP rendezvous server", l}
+			}
+			rr.RendezvousServers = append(rr.RendezvousServers, l.token)
+		case _BLANK:
+			// Ok
+		default:
+			return nil, &ParseError{"bad HIP", l}
+		}
+		l = <-c
+	}
+	return rr, nil
 }
-*/
\ No newline at end of file
+
+func setTLSA(h RR_Header, c chan Lex) (RR, error) {
+	rr := new(RR_TLSA)
+	rr.Hdr = h
+
+	l := <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad TLSA", l}
+	} else {
+		rr.Usage = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad TLSA", l}
+	} else {
+		rr.Selector = uint8(i)
+	}
+	<-c // _BLANK
+	l = <-c
+	if i, e := strconv.Atoi(l.token); e != nil {
+		return nil, &ParseError{"bad TLSA", l}
+	} else {
+		rr.MatchingType = uint8(i)
+	}
+	<-c // _BLANK
+	// Get the remaining data (hex encoded, possibly split over
+	// several blank-separated chunks) until we see a NEWLINE
+	l = <-c
+	var s string
+	for l.value != _NEWLINE && l.value != _EOF {
+		switch l.value {
+		case _STRING:
+			s += l.token
+		case _BLANK:
+			// Ok
+		default:
+			return nil, &ParseError{"bad TLSA", l}
+		}
+		l = <-c
+	}
+	rr.Certificate = s
+	return rr, nil
+}
\ No newline at end of file
@@ -0,0 +1,85 @@
+package dns
+
+import "testing"
+
+func mustSetRR(t *testing.T, rrtype uint16, fields []string, set func(RR_Header, chan Lex) (RR, error)) RR {
+	t.Helper()
+	h := RR_Header{Name: "example.com.", Rrtype: rrtype}
+	rr, err := set(h, fieldsToLex(fields, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rr
+}
+
+func TestSetDS(t *testing.T) {
+	rr := mustSetRR(t, TypeDS, []string{"60485", "5", "1", "2BB183AF5F22588179A53B0A98631FAD1A292118"}, setDS).(*RR_DS)
+	if rr.KeyTag != 60485 || rr.Algorithm != 5 || rr.DigestType != 1 {
+		t.Fatalf("bad DS fields: %+v", rr)
+	}
+	if rr.Digest != "2BB183AF5F22588179A53B0A98631FAD1A292118" {
+		t.Fatalf("bad DS digest: %q", rr.Digest)
+	}
+}
+
+func TestSetDNSKEY(t *testing.T) {
+	rr := mustSetRR(t, TypeDNSKEY, []string{"256", "3", "5", "AQPSKmynfzW4kyBv015MUG2DeIQ3", "Cbl+BBZH4b/0PY1kxkmvHjcZc8nokfzj31GajIQKY+", "5CptLr3buXA10hWqTkF7j1RQo="}, setDNSKEY).(*RR_DNSKEY)
+	if rr.Flags != 256 || rr.Protocol != 3 || rr.Algorithm != 5 {
+		t.Fatalf("bad DNSKEY fields: %+v", rr)
+	}
+	want := "AQPSKmynfzW4kyBv015MUG2DeIQ3Cbl+BBZH4b/0PY1kxkmvHjcZc8nokfzj31GajIQKY+5CptLr3buXA10hWqTkF7j1RQo="
+	if rr.PublicKey != want {
+		t.Fatalf("base64 key not rejoined across blanks: got %q want %q", rr.PublicKey, want)
+	}
+}
+
+func TestSetSSHFP(t *testing.T) {
+	rr := mustSetRR(t, TypeSSHFP, []string{"2", "1", "123456789abcdef67890123456789abcdef67890"}, setSSHFP).(*RR_SSHFP)
+	if rr.Algorithm != 2 || rr.Type != 1 {
+		t.Fatalf("bad SSHFP fields: %+v", rr)
+	}
+	if rr.FingerPrint != "123456789abcdef67890123456789abcdef67890" {
+		t.Fatalf("bad SSHFP fingerprint: %q", rr.FingerPrint)
+	}
+}
+
+func TestSetNSEC3PARAM(t *testing.T) {
+	rr := mustSetRR(t, TypeNSEC3PARAM, []string{"1", "0", "12", "aabbccdd"}, setNSEC3PARAM).(*RR_NSEC3PARAM)
+	if rr.Hash != 1 || rr.Flags != 0 || rr.Iterations != 12 {
+		t.Fatalf("bad NSEC3PARAM fields: %+v", rr)
+	}
+	if rr.Salt != "aabbccdd" || rr.SaltLength != uint8(len("aabbccdd")) {
+		t.Fatalf("bad NSEC3PARAM salt: %+v", rr)
+	}
+}
+
+func TestSetTLSA(t *testing.T) {
+	rr := mustSetRR(t, TypeTLSA, []string{"3", "1", "1", "0a", "1b", "2c"}, setTLSA).(*RR_TLSA)
+	if rr.Usage != 3 || rr.Selector != 1 || rr.MatchingType != 1 {
+		t.Fatalf("bad TLSA fields: %+v", rr)
+	}
+	if rr.Certificate != "0a1b2c" {
+		t.Fatalf("bad TLSA certificate: %q", rr.Certificate)
+	}
+}
+
+func TestSetHIP(t *testing.T) {
+	rr := mustSetRR(t, TypeHIP, []string{"2", "200100107B1A74DF365639CC39F1D578", "AwEAAbdxyhNuSutc5EMzxTs9LBPCIkOF", "rvs.example.com."}, setHIP).(*RR_HIP)
+	if rr.PublicKeyAlgorithm != 2 {
+		t.Fatalf("bad HIP algorithm: %+v", rr)
+	}
+	if rr.Hit != "200100107B1A74DF365639CC39F1D578" {
+		t.Fatalf("bad HIP hit: %q", rr.Hit)
+	}
+	if len(rr.RendezvousServers) != 1 || rr.RendezvousServers[0] != "rvs.example.com." {
+		t.Fatalf("bad HIP rendezvous servers: %+v", rr.RendezvousServers)
+	}
+}
+
+func TestSetHIPBadRendezvousServer(t *testing.T) {
+	h := RR_Header{Name: "example.com.", Rrtype: TypeHIP}
+	_, err := setHIP(h, fieldsToLex([]string{"2", "200100107B1A74DF365639CC39F1D578", "AwEAAbdxyhNuSutc5EMzxTs9LBPCIkOF", ".."}, 1))
+	if err == nil {
+		t.Fatalf("expected error for invalid rendezvous server, got none")
+	}
+}